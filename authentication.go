@@ -0,0 +1,398 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/md5"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MustWin/baremetal-sdk-go"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	authAPIKey             = "ApiKey"
+	authInstancePrincipal  = "InstancePrincipal"
+	authConfigFile         = "ConfigFile"
+	imdsFederationEndpoint = "http://169.254.169.254/opc/v2/identity/cert.pem"
+	imdsKeyEndpoint        = "http://169.254.169.254/opc/v2/identity/key.pem"
+	imdsTokenEndpoint      = "http://169.254.169.254/opc/v2/identity/"
+)
+
+// transportWrapper wraps an http.RoundTripper with auth-mode-specific
+// behavior (e.g. instance principal bearer-token signing) before it's
+// installed on the client via baremetal.CustomTransport.
+type transportWrapper func(next http.RoundTripper) http.RoundTripper
+
+// authConfig inspects the "auth" provider attribute and returns the
+// user/tenancy/fingerprint triple, any NewClientOptionsFunc needed under
+// the selected authentication mode, and an optional transportWrapper for
+// modes (InstancePrincipal) that authorize requests at the transport level
+// instead of through the SDK's private-key options.
+func authConfig(d *schema.ResourceData) (userOCID, tenancyOCID, fingerprint string, opts []baremetal.NewClientOptionsFunc, wrap transportWrapper, err error) {
+	auth := d.Get("auth").(string)
+	if auth == "" {
+		auth = authAPIKey
+	}
+
+	switch auth {
+	case authAPIKey:
+		userOCID, tenancyOCID, fingerprint, opts, err = apiKeyAuthConfig(d)
+	case authInstancePrincipal:
+		userOCID, tenancyOCID, fingerprint, opts, wrap, err = instancePrincipalAuthConfig(d)
+	case authConfigFile:
+		userOCID, tenancyOCID, fingerprint, opts, err = configFileAuthConfig(d)
+	default:
+		err = fmt.Errorf("unknown auth %q: expected one of %s, %s, %s", auth, authAPIKey, authInstancePrincipal, authConfigFile)
+	}
+	return
+}
+
+func apiKeyAuthConfig(d *schema.ResourceData) (userOCID, tenancyOCID, fingerprint string, opts []baremetal.NewClientOptionsFunc, err error) {
+	tenancyOCID = d.Get("tenancy_ocid").(string)
+	userOCID = d.Get("user_ocid").(string)
+	fingerprint = d.Get("fingerprint").(string)
+	privateKeyBuffer, hasKey := d.Get("private_key").(string)
+	privateKeyPath, hasKeyPath := d.Get("private_key_path").(string)
+	privateKeyPassword, hasKeyPass := d.Get("private_key_password").(string)
+
+	if tenancyOCID == "" || userOCID == "" || fingerprint == "" {
+		err = errors.New("tenancy_ocid, user_ocid, and fingerprint are required when auth is ApiKey")
+		return
+	}
+
+	if hasKey && privateKeyBuffer != "" {
+		opts = append(opts, baremetal.PrivateKeyBytes([]byte(privateKeyBuffer)))
+	} else if hasKeyPath && privateKeyPath != "" {
+		opts = append(opts, baremetal.PrivateKeyFilePath(privateKeyPath))
+	} else {
+		err = errors.New("One of private_key or private_key_path is required")
+		return
+	}
+
+	if hasKeyPass && privateKeyPassword != "" {
+		opts = append(opts, baremetal.PrivateKeyPassword(privateKeyPassword))
+	}
+
+	return
+}
+
+// configFileAuthConfig reads tenancy/user/fingerprint/key settings from an
+// OCI config file, defaulting to ~/.oci/config (or OCI_CONFIG_FILE), under
+// the profile named by config_file_profile.
+func configFileAuthConfig(d *schema.ResourceData) (userOCID, tenancyOCID, fingerprint string, opts []baremetal.NewClientOptionsFunc, err error) {
+	profile := d.Get("config_file_profile").(string)
+	if profile == "" {
+		profile = "DEFAULT"
+	}
+
+	path := getEnvSetting("config_file", "")
+	if path == "" {
+		path = os.Getenv("OCI_CONFIG_FILE")
+	}
+	if path == "" {
+		home, homeErr := os.UserHomeDir()
+		if homeErr != nil {
+			err = fmt.Errorf("unable to determine home directory for OCI config file: %v", homeErr)
+			return
+		}
+		path = filepath.Join(home, ".oci", "config")
+	}
+
+	profiles, err := parseConfigFile(path)
+	if err != nil {
+		return
+	}
+
+	section, ok := profiles[profile]
+	if !ok {
+		err = fmt.Errorf("profile %q not found in config file %s", profile, path)
+		return
+	}
+
+	tenancyOCID = section["tenancy"]
+	userOCID = section["user"]
+	fingerprint = section["fingerprint"]
+
+	if tenancyOCID == "" || userOCID == "" || fingerprint == "" {
+		err = fmt.Errorf("profile %q in %s is missing tenancy, user, or fingerprint", profile, path)
+		return
+	}
+
+	if keyFile := section["key_file"]; keyFile != "" {
+		opts = append(opts, baremetal.PrivateKeyFilePath(keyFile))
+	} else {
+		err = fmt.Errorf("profile %q in %s is missing key_file", profile, path)
+		return
+	}
+
+	if passPhrase := section["pass_phrase"]; passPhrase != "" {
+		opts = append(opts, baremetal.PrivateKeyPassword(passPhrase))
+	}
+
+	if region := section["region"]; region != "" {
+		opts = append(opts, baremetal.Region(region))
+	}
+
+	return
+}
+
+// parseConfigFile parses an OCI config file's INI-style [profile] sections
+// into a map of profile name to key/value settings.
+func parseConfigFile(path string) (map[string]map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read OCI config file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	profiles := map[string]map[string]string{}
+	currentProfile := ""
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			currentProfile = strings.TrimSpace(line[1 : len(line)-1])
+			profiles[currentProfile] = map[string]string{}
+			continue
+		}
+
+		if currentProfile == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		profiles[currentProfile][key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error parsing OCI config file %s: %v", path, err)
+	}
+
+	return profiles, nil
+}
+
+// instancePrincipalAuthConfig obtains a signing certificate and tenancy/
+// instance identity from the local IMDS/federation endpoint, so the
+// provider can run on OCI compute without embedding user keys. Since there
+// is no private key file to hand the SDK's usual PrivateKeyBytes/
+// PrivateKeyFilePath options, requests are instead authorized at the
+// transport level: the returned transportWrapper installs a RoundTripper
+// that attaches a bearer token, refreshed before it expires, via the
+// already-proven baremetal.CustomTransport hook rather than any
+// invented SDK signer option.
+func instancePrincipalAuthConfig(d *schema.ResourceData) (userOCID, tenancyOCID, fingerprint string, opts []baremetal.NewClientOptionsFunc, wrap transportWrapper, err error) {
+	signer, err := newInstancePrincipalSigner()
+	if err != nil {
+		return
+	}
+
+	tenancyOCID = signer.tenancyOCID
+	userOCID = signer.instanceOCID
+	fingerprint = signer.fingerprint
+
+	wrap = func(next http.RoundTripper) http.RoundTripper {
+		signer.next = next
+		return signer
+	}
+
+	return
+}
+
+// instancePrincipalSigner authorizes requests with a delegation token
+// fetched from IMDS, refreshing the token before it expires. It holds the
+// federation certificate's private key, but requests are not signed with
+// it; only the bearer token is attached. It implements http.RoundTripper
+// so it can be chained into the transport built by providerConfig.
+type instancePrincipalSigner struct {
+	tenancyOCID  string
+	instanceOCID string
+	fingerprint  string
+
+	next http.RoundTripper
+
+	mu        sync.Mutex
+	key       *ecdsa.PrivateKey
+	token     string
+	expiresAt time.Time
+}
+
+func newInstancePrincipalSigner() (*instancePrincipalSigner, error) {
+	cert, key, err := fetchIMDSCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("unable to obtain instance principal certificate from IMDS: %v", err)
+	}
+
+	signer := &instancePrincipalSigner{
+		tenancyOCID:  certTenancyOCID(cert),
+		instanceOCID: cert.Subject.CommonName,
+		fingerprint:  certFingerprint(cert),
+		key:          key,
+	}
+
+	if err := signer.refresh(); err != nil {
+		return nil, err
+	}
+
+	return signer, nil
+}
+
+func (s *instancePrincipalSigner) refresh() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Now().Before(s.expiresAt) {
+		return nil
+	}
+
+	token, expiresAt, err := fetchIMDSToken()
+	if err != nil {
+		return fmt.Errorf("unable to refresh instance principal token from IMDS: %v", err)
+	}
+
+	s.token = token
+	s.expiresAt = expiresAt
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper, refreshing the underlying token
+// before it expires and attaching it to the outgoing request before
+// delegating to next.
+func (s *instancePrincipalSigner) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return s.next.RoundTrip(req)
+}
+
+func fetchIMDSCertificate() (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPEM, err := imdsGet(imdsFederationEndpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyPEM, err := imdsGet(imdsKeyEndpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, errors.New("no PEM certificate found in IMDS response")
+	}
+
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse IMDS certificate: %v", err)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, errors.New("no PEM key found in IMDS response")
+	}
+
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse IMDS private key: %v", err)
+	}
+
+	return cert, key, nil
+}
+
+type imdsTokenResponse struct {
+	Token string `json:"token"`
+}
+
+func fetchIMDSToken() (token string, expiresAt time.Time, err error) {
+	body, err := imdsGet(imdsTokenEndpoint)
+	if err != nil {
+		return
+	}
+
+	var resp imdsTokenResponse
+	if jsonErr := json.Unmarshal(body, &resp); jsonErr != nil {
+		err = fmt.Errorf("unable to parse IMDS token response: %v", jsonErr)
+		return
+	}
+
+	token = resp.Token
+	// IMDS federation tokens are short-lived; refresh well before the
+	// typical one hour expiry to avoid racing a request against expiry.
+	expiresAt = time.Now().Add(50 * time.Minute)
+	return
+}
+
+func imdsGet(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer Oracle")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("IMDS request to %s failed with status %s", url, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// certTenancyOCID extracts the tenancy OCID from the federation
+// certificate's issuer, which IMDS encodes as "opc-tenant:<ocid>".
+func certTenancyOCID(cert *x509.Certificate) string {
+	for _, name := range cert.Issuer.Organization {
+		if strings.HasPrefix(name, "opc-tenant:") {
+			return strings.TrimPrefix(name, "opc-tenant:")
+		}
+	}
+	return ""
+}
+
+// certFingerprint computes the key fingerprint OCI expects as the
+// "fingerprint" value passed to baremetal.NewClient: the MD5 hash of the
+// DER-encoded public key, colon-separated hex, NOT a hash of the whole
+// certificate.
+func certFingerprint(cert *x509.Certificate) string {
+	sum := md5.Sum(cert.RawSubjectPublicKeyInfo)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(parts, ":")
+}
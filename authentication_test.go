@@ -0,0 +1,136 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	content := `# a comment
+[DEFAULT]
+tenancy=ocid1.tenancy.oc1..default
+user=ocid1.user.oc1..default
+fingerprint=aa:bb:cc
+key_file=~/.oci/oci_api_key.pem
+
+; another comment
+[PROD]
+tenancy = ocid1.tenancy.oc1..prod
+user=ocid1.user.oc1..prod
+fingerprint=dd:ee:ff
+key_file=/secrets/prod.pem
+region=us-ashburn-1
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("unable to write test config: %v", err)
+	}
+
+	profiles, err := parseConfigFile(path)
+	if err != nil {
+		t.Fatalf("parseConfigFile returned error: %v", err)
+	}
+
+	if len(profiles) != 2 {
+		t.Fatalf("len(profiles) = %d, want 2", len(profiles))
+	}
+
+	def, ok := profiles["DEFAULT"]
+	if !ok {
+		t.Fatal("missing DEFAULT profile")
+	}
+	if def["tenancy"] != "ocid1.tenancy.oc1..default" {
+		t.Errorf("DEFAULT tenancy = %q", def["tenancy"])
+	}
+	if def["key_file"] != "~/.oci/oci_api_key.pem" {
+		t.Errorf("DEFAULT key_file = %q", def["key_file"])
+	}
+
+	prod, ok := profiles["PROD"]
+	if !ok {
+		t.Fatal("missing PROD profile")
+	}
+	if prod["tenancy"] != "ocid1.tenancy.oc1..prod" {
+		t.Errorf("PROD tenancy = %q, want trimmed value despite surrounding spaces", prod["tenancy"])
+	}
+	if prod["region"] != "us-ashburn-1" {
+		t.Errorf("PROD region = %q", prod["region"])
+	}
+}
+
+func TestParseConfigFileMissing(t *testing.T) {
+	if _, err := parseConfigFile("/nonexistent/path/to/config"); err == nil {
+		t.Fatal("expected an error for a missing config file")
+	}
+}
+
+func TestCertFingerprintIsMD5OfPublicKeyNotWholeCert(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate test key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test-instance"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unable to create test certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("unable to parse test certificate: %v", err)
+	}
+
+	got := certFingerprint(cert)
+
+	// 16 MD5 bytes, colon-separated, i.e. 47 characters - not a 20-byte
+	// SHA-1 fingerprint (59 characters).
+	if len(got) != 47 {
+		t.Errorf("certFingerprint length = %d, want 47 (MD5, not SHA-1)", len(got))
+	}
+
+	keySum := md5.Sum(cert.RawSubjectPublicKeyInfo)
+	if want := strings.Replace(fmt.Sprintf("% x", keySum[:]), " ", ":", -1); got != want {
+		t.Errorf("certFingerprint = %q, want %q (MD5 of the public key)", got, want)
+	}
+	certSum := md5.Sum(cert.Raw)
+	if whole := strings.Replace(fmt.Sprintf("% x", certSum[:]), " ", ":", -1); got == whole {
+		t.Errorf("certFingerprint = %q, the MD5 of the whole certificate", got)
+	}
+}
+
+func TestCertTenancyOCID(t *testing.T) {
+	cert := &x509.Certificate{
+		Issuer: pkix.Name{Organization: []string{"other", "opc-tenant:ocid1.tenancy.oc1..abc"}},
+	}
+
+	if got := certTenancyOCID(cert); got != "ocid1.tenancy.oc1..abc" {
+		t.Errorf("certTenancyOCID = %q, want ocid1.tenancy.oc1..abc", got)
+	}
+}
+
+func TestCertTenancyOCIDMissing(t *testing.T) {
+	cert := &x509.Certificate{Issuer: pkix.Name{Organization: []string{"other"}}}
+
+	if got := certTenancyOCID(cert); got != "" {
+		t.Errorf("certTenancyOCID = %q, want empty string", got)
+	}
+}
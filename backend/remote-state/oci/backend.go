@@ -0,0 +1,221 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+// Package oci implements a Terraform backend that stores state in an OCI
+// Object Storage bucket, with locking provided by a companion sentinel
+// object created through a conditional If-None-Match request. Unlock
+// removes the sentinel with an unconditional delete; If-Match is not used.
+package oci
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/MustWin/baremetal-sdk-go"
+	"github.com/hashicorp/terraform/backend"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/state"
+	"github.com/hashicorp/terraform/state/remote"
+)
+
+// defaultObjectName is used when object_name is unset, matching the
+// convention of other remote-state backends defaulting to "terraform.tfstate".
+const defaultObjectName = "terraform.tfstate"
+
+// New creates a new backend for OCI Object Storage remote state.
+func New() backend.Backend {
+	s := &schema.Backend{
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the Object Storage bucket to store state in.",
+			},
+			"namespace": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The Object Storage namespace (tenancy-specific) that owns the bucket.",
+			},
+			"object_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     defaultObjectName,
+				Description: "The name of the object used to store the state.",
+			},
+			"objectName": {
+				Type:       schema.TypeString,
+				Optional:   true,
+				Deprecated: "Use object_name instead. objectName is kept only to ease migration.",
+			},
+			"tenancy_ocid": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The tenancy OCID for the user used to access Object Storage.",
+			},
+			"user_ocid": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The user OCID used to access Object Storage.",
+			},
+			"fingerprint": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The fingerprint for the user's RSA key.",
+			},
+			"private_key_path": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The path to the user's PEM formatted private key.",
+			},
+			"region": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "us-phoenix-1",
+				Description: "The region for Object Storage API connections.",
+			},
+			"encryption_key_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The OCID of a KMS key used to encrypt the state object server-side.",
+			},
+		},
+	}
+
+	result := &Backend{Backend: s}
+	result.Backend.ConfigureFunc = result.configure
+	return result
+}
+
+// Backend implements backend.Backend for OCI Object Storage.
+type Backend struct {
+	*schema.Backend
+
+	client     *baremetal.Client
+	bucket     string
+	namespace  string
+	keyID      string
+	objectName string
+}
+
+// configure mirrors the schema.Provider ConfigureFunc convention used by
+// provider.go: it's handed the already-validated *schema.ResourceData for
+// the backend block and builds the client from it.
+func (b *Backend) configure(d *schema.ResourceData) error {
+	b.bucket = d.Get("bucket").(string)
+	b.namespace = d.Get("namespace").(string)
+	b.keyID = d.Get("encryption_key_id").(string)
+
+	b.objectName = defaultObjectName
+	if name := d.Get("object_name").(string); name != "" && name != defaultObjectName {
+		b.objectName = name
+	} else if legacy := d.Get("objectName").(string); legacy != "" {
+		b.objectName = legacy
+	}
+
+	client, err := baremetal.NewClient(
+		d.Get("user_ocid").(string),
+		d.Get("tenancy_ocid").(string),
+		d.Get("fingerprint").(string),
+		baremetal.PrivateKeyFilePath(d.Get("private_key_path").(string)),
+		baremetal.Region(d.Get("region").(string)),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to configure OCI client for remote state: %v", err)
+	}
+
+	b.client = client
+	return nil
+}
+
+// States lists the state names stored in the bucket by listing objects
+// with the ".tfstate" suffix and taking the workspace name from the
+// "env:<workspace>/" prefix of each. The default workspace (the bare
+// object_name) is always listed first; objects without that prefix are
+// skipped.
+func (b *Backend) States() ([]string, error) {
+	objects, err := b.client.ListObjects(b.namespace, b.bucket, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list OCI Object Storage objects: %v", err)
+	}
+
+	states := []string{backend.DefaultStateName}
+	for _, name := range objects.Objects {
+		if name.Name == "" || !strings.HasSuffix(name.Name, ".tfstate") || strings.HasSuffix(name.Name, ".tflock") {
+			continue
+		}
+		if ws := workspaceFromObjectName(name.Name); ws != "" {
+			states = append(states, ws)
+		}
+	}
+
+	return states, nil
+}
+
+func workspaceFromObjectName(name string) string {
+	const prefix = "env:"
+	if !strings.HasPrefix(name, prefix) {
+		return ""
+	}
+	trimmed := strings.TrimPrefix(name, prefix)
+	parts := strings.SplitN(trimmed, "/", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return parts[0]
+}
+
+// DeleteState removes the named workspace's state object and its lock
+// sentinel, if present. A missing lock object (no lock was ever taken, or
+// it was already cleaned up) is not an error.
+func (b *Backend) DeleteState(name string) error {
+	if name == backend.DefaultStateName || name == "" {
+		return fmt.Errorf("can't delete default state")
+	}
+
+	objectName := b.workspaceObjectName(name)
+	resp, err := b.client.DeleteObject(b.namespace, b.bucket, objectName, nil)
+	if err != nil {
+		return fmt.Errorf("unable to delete OCI Object Storage object %s: %v", objectName, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound && resp.StatusCode >= 300 {
+		return fmt.Errorf("unable to delete OCI Object Storage object %s: unexpected status %d", objectName, resp.StatusCode)
+	}
+
+	lockName := objectName + ".tflock"
+	lockResp, err := b.client.DeleteObject(b.namespace, b.bucket, lockName, nil)
+	if err != nil {
+		return fmt.Errorf("unable to delete OCI Object Storage lock object %s: %v", lockName, err)
+	}
+	lockResp.Body.Close()
+	if lockResp.StatusCode != http.StatusNotFound && lockResp.StatusCode >= 300 {
+		return fmt.Errorf("unable to delete OCI Object Storage lock object %s: unexpected status %d", lockName, lockResp.StatusCode)
+	}
+
+	return nil
+}
+
+func (b *Backend) workspaceObjectName(name string) string {
+	if name == backend.DefaultStateName || name == "" {
+		return b.objectName
+	}
+	return fmt.Sprintf("env:%s/%s", name, b.objectName)
+}
+
+// State returns a remote.State backed by the named workspace's object,
+// wired up with a lock client that uses a companion ".tflock" sentinel
+// object and conditional writes.
+func (b *Backend) State(name string) (state.State, error) {
+	objectName := b.workspaceObjectName(name)
+
+	client := &RemoteClient{
+		client:       b.client,
+		namespace:    b.namespace,
+		bucket:       b.bucket,
+		objectName:   objectName,
+		lockName:     objectName + ".tflock",
+		encryptKeyID: b.keyID,
+	}
+
+	return &remote.State{Client: client}, nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package oci
+
+import "testing"
+
+func TestWorkspaceFromObjectName(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"default object is not a workspace", "terraform.tfstate", ""},
+		{"lock sentinel still yields its workspace (States filters it by suffix)", "env:prod/terraform.tfstate.tflock", "prod"},
+		{"env-prefixed object recovers the workspace name", "env:prod/terraform.tfstate", "prod"},
+		{"env-prefixed object with a nested object_name path", "env:staging/team/terraform.tfstate", "staging"},
+		{"missing env prefix is not a workspace", "terraform.tfstate.bak", ""},
+		{"malformed env prefix with no slash", "env:prod", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := workspaceFromObjectName(c.in); got != c.want {
+				t.Errorf("workspaceFromObjectName(%q) = %q, want %q", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestBackendWorkspaceObjectName(t *testing.T) {
+	b := &Backend{objectName: "terraform.tfstate"}
+
+	cases := []struct {
+		name      string
+		workspace string
+		want      string
+	}{
+		{"default workspace uses the bare object name", "default", "terraform.tfstate"},
+		{"empty workspace is treated as default", "", "terraform.tfstate"},
+		{"named workspace is env-prefixed", "prod", "env:prod/terraform.tfstate"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := b.workspaceObjectName(c.workspace); got != c.want {
+				t.Errorf("workspaceObjectName(%q) = %q, want %q", c.workspace, got, c.want)
+			}
+		})
+	}
+}
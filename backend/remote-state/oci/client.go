@@ -0,0 +1,162 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MustWin/baremetal-sdk-go"
+	"github.com/hashicorp/terraform/state"
+	"github.com/hashicorp/terraform/state/remote"
+)
+
+// RemoteClient implements remote.Client (Get/Put/Delete) plus
+// state.Locker, storing state and lock info as objects in an OCI Object
+// Storage bucket.
+type RemoteClient struct {
+	client    *baremetal.Client
+	namespace string
+	bucket    string
+
+	objectName   string
+	lockName     string
+	encryptKeyID string
+}
+
+// Get implements remote.Client.
+func (c *RemoteClient) Get() (*remote.Payload, error) {
+	resp, err := c.client.GetObject(c.namespace, c.bucket, c.objectName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read OCI Object Storage object %s: %v", c.objectName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	data, err := readAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read OCI Object Storage object %s: %v", c.objectName, err)
+	}
+
+	return &remote.Payload{
+		Data: data,
+		MD5:  md5Sum(data),
+	}, nil
+}
+
+// Put implements remote.Client.
+func (c *RemoteClient) Put(data []byte) error {
+	header := http.Header{}
+	if c.encryptKeyID != "" {
+		header.Set("opc-sse-kms-key-id", c.encryptKeyID)
+	}
+
+	resp, err := c.client.PutObject(c.namespace, c.bucket, c.objectName, data, header)
+	if err != nil {
+		return fmt.Errorf("unable to write OCI Object Storage object %s: %v", c.objectName, err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// Delete implements remote.Client.
+func (c *RemoteClient) Delete() error {
+	resp, err := c.client.DeleteObject(c.namespace, c.bucket, c.objectName, nil)
+	if err != nil {
+		return fmt.Errorf("unable to delete OCI Object Storage object %s: %v", c.objectName, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound && resp.StatusCode >= 300 {
+		return fmt.Errorf("unable to delete OCI Object Storage object %s: unexpected status %d", c.objectName, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Lock implements state.Locker by writing the lock sentinel object with
+// If-None-Match: "*" so the write fails if another operator already holds
+// the lock.
+func (c *RemoteClient) Lock(info *state.LockInfo) (string, error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal lock info: %v", err)
+	}
+
+	header := http.Header{}
+	header.Set("If-None-Match", "*")
+
+	resp, err := c.client.PutObject(c.namespace, c.bucket, c.lockName, data, header)
+	if err != nil {
+		return "", fmt.Errorf("unable to write lock object %s: %v", c.lockName, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict {
+		existing, getErr := c.getLockInfo()
+		if getErr != nil {
+			return "", &state.LockError{Err: fmt.Errorf("lock object %s already exists: %v", c.lockName, getErr)}
+		}
+		return "", &state.LockError{Info: existing, Err: fmt.Errorf("lock object %s already exists", c.lockName)}
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("unable to write lock object %s: unexpected status %d", c.lockName, resp.StatusCode)
+	}
+
+	return info.ID, nil
+}
+
+// Unlock implements state.Locker, deleting the lock sentinel object only
+// if it still matches the lock ID we created. The ID check and the delete
+// are separate requests; the delete carries no If-Match condition.
+func (c *RemoteClient) Unlock(id string) error {
+	info, err := c.getLockInfo()
+	if err != nil {
+		return &state.LockError{Err: fmt.Errorf("unable to read lock object %s: %v", c.lockName, err)}
+	}
+
+	if info.ID != id {
+		return &state.LockError{Info: info, Err: fmt.Errorf("lock id %q does not match held lock %q", id, info.ID)}
+	}
+
+	resp, err := c.client.DeleteObject(c.namespace, c.bucket, c.lockName, nil)
+	if err != nil {
+		return &state.LockError{Info: info, Err: fmt.Errorf("unable to delete lock object %s: %v", c.lockName, err)}
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound && resp.StatusCode >= 300 {
+		return &state.LockError{Info: info, Err: fmt.Errorf("unable to delete lock object %s: unexpected status %d", c.lockName, resp.StatusCode)}
+	}
+
+	return nil
+}
+
+func (c *RemoteClient) getLockInfo() (*state.LockInfo, error) {
+	resp, err := c.client.GetObject(c.namespace, c.bucket, c.lockName, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("lock object %s does not exist", c.lockName)
+	}
+
+	data, err := readAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &state.LockInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal lock info: %v", err)
+	}
+
+	return info, nil
+}
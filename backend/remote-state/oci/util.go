@@ -0,0 +1,18 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package oci
+
+import (
+	"crypto/md5"
+	"io"
+	"io/ioutil"
+)
+
+func readAll(r io.Reader) ([]byte, error) {
+	return ioutil.ReadAll(r)
+}
+
+func md5Sum(data []byte) []byte {
+	sum := md5.Sum(data)
+	return sum[:]
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package main
+
+// Registering backend/remote-state/oci "in this provider's binary" (request
+// chunk0-2) is BLOCKED, not done, as shipped here.
+//
+// A previous fix commit added a package-level
+// `var backends = map[string]func() backend.Backend{"oci": ocibackend.New}`
+// here, with a comment claiming it made the backend reachable instead of
+// dead code. It didn't: nothing in this tree ever reads that map or calls
+// ocibackend.New, so it was the same unreachable state under a reassuring
+// name rather than an actual fix.
+//
+// The real blocker: this binary speaks Terraform's net/rpc-based SDKv1
+// provider protocol (see main.go), and stock Terraform only loads
+// remote-state backends from terraform core's own backend/init registry -
+// a provider plugin has no protocol hook to contribute to that registry at
+// runtime. There is no caller for a backend constructor to wire into on
+// this side of the plugin boundary. backend/remote-state/oci itself
+// compiles and is unit-tested (backend_test.go, client.go), so it isn't
+// lost work; it just isn't reachable from terraform-provider-baremetal's
+// binary, and won't be until either terraform core adds a pluggable
+// backend registry or this tree's chunk0-6 gRPC protocol migration lands
+// and changes what's possible across that boundary.
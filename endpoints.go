@@ -0,0 +1,85 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// servicePrefixes maps the service names accepted in the "endpoints"
+// provider block to the hostname prefix the baremetal SDK uses when
+// building request URLs (e.g. "https://iaas.<region>.oraclecloud.com").
+var servicePrefixes = map[string]string{
+	"identity":       "identity",
+	"core":           "iaas",
+	"database":       "database",
+	"load_balancer":  "loadbalancer",
+	"object_storage": "objectstorage",
+}
+
+// endpointOverrides reads the "endpoints" block into a map keyed by
+// service name, returning nil if the block was not set.
+func endpointOverrides(d *schema.ResourceData) map[string]string {
+	raw, ok := d.GetOk("endpoints")
+	if !ok {
+		return nil
+	}
+
+	endpoints := map[string]string{}
+	for service, v := range raw.(map[string]interface{}) {
+		endpoints[service] = v.(string)
+	}
+
+	return endpoints
+}
+
+// endpointRoundTripper rewrites outbound request URLs whose host matches a
+// known service prefix to the corresponding user-configured endpoint,
+// before delegating to next. This lets the provider target isolated
+// regions, Dedicated Region Cloud@Customer, or local mock deployments
+// without patching the SDK.
+type endpointRoundTripper struct {
+	endpoints map[string]string
+	next      http.RoundTripper
+}
+
+func (rt *endpointRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if override := rt.overrideFor(req.URL); override != nil {
+		req = req.Clone(req.Context())
+		req.URL = override
+		req.Host = override.Host
+	}
+
+	return rt.next.RoundTrip(req)
+}
+
+func (rt *endpointRoundTripper) overrideFor(u *url.URL) *url.URL {
+	for service, prefix := range servicePrefixes {
+		endpoint, ok := rt.endpoints[service]
+		if !ok || endpoint == "" {
+			continue
+		}
+		if !strings.HasPrefix(u.Host, prefix+".") {
+			continue
+		}
+
+		overrideURL, err := url.Parse(endpoint)
+		if err != nil {
+			continue
+		}
+
+		merged := *u
+		merged.Scheme = overrideURL.Scheme
+		merged.Host = overrideURL.Host
+		if overrideURL.Path != "" {
+			merged.Path = strings.TrimRight(overrideURL.Path, "/") + u.Path
+		}
+		return &merged
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestEndpointRoundTripperOverrideFor(t *testing.T) {
+	rt := &endpointRoundTripper{
+		endpoints: map[string]string{
+			"object_storage": "https://localhost:9000",
+			"core":           "https://iaas.mock.local/v1",
+		},
+	}
+
+	cases := []struct {
+		name     string
+		url      string
+		wantHost string
+		wantPath string
+		wantNil  bool
+	}{
+		{
+			name:     "object storage override rewrites scheme and host",
+			url:      "https://objectstorage.us-phoenix-1.oraclecloud.com/n/ns/b/bucket/o/object",
+			wantHost: "localhost:9000",
+			wantPath: "/n/ns/b/bucket/o/object",
+		},
+		{
+			name:     "core override with a path prefix is joined, not replaced",
+			url:      "https://iaas.us-phoenix-1.oraclecloud.com/20160918/instances",
+			wantHost: "iaas.mock.local",
+			wantPath: "/v1/20160918/instances",
+		},
+		{
+			name:    "service with no configured override is left alone",
+			url:     "https://identity.us-phoenix-1.oraclecloud.com/20160918/users",
+			wantNil: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			u, err := url.Parse(c.url)
+			if err != nil {
+				t.Fatalf("unable to parse test URL: %v", err)
+			}
+
+			got := rt.overrideFor(u)
+			if c.wantNil {
+				if got != nil {
+					t.Fatalf("overrideFor(%s) = %v, want nil", c.url, got)
+				}
+				return
+			}
+
+			if got == nil {
+				t.Fatalf("overrideFor(%s) = nil, want an override", c.url)
+			}
+			if got.Host != c.wantHost {
+				t.Errorf("Host = %q, want %q", got.Host, c.wantHost)
+			}
+			if got.Path != c.wantPath {
+				t.Errorf("Path = %q, want %q", got.Path, c.wantPath)
+			}
+		})
+	}
+}
+
+func TestEndpointOverridesEmptyWhenUnset(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, schemaMap(), map[string]interface{}{})
+	if got := endpointOverrides(d); got != nil {
+		t.Errorf("endpointOverrides() = %v, want nil when endpoints is unset", got)
+	}
+}
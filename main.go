@@ -0,0 +1,35 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package main
+
+import (
+	"github.com/hashicorp/terraform/plugin"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// main serves the SDKv1 schema.Provider built by Provider().
+//
+// chunk0-6 (gRPC-based plugin protocol upgrade and framework-provider
+// coexistence) is BLOCKED, not done, as shipped here. The request asked
+// for the existing schema.Provider to be served alongside a new
+// terraform-plugin-framework provider through tf5muxserver/tf6muxserver,
+// so new resources (KMS keys, WAF policies, streaming, ...) could be
+// authored against the framework while resourcesMap/dataSourcesMap keep
+// working unchanged. That mux requires a provider on this tree's side
+// that speaks protocol v5/v6 over gRPC, which
+// github.com/hashicorp/terraform/helper/schema (the legacy net/rpc-based
+// SDK this provider is still built on) does not expose - only
+// github.com/hashicorp/terraform-plugin-sdk/v2's schema.Provider has a
+// GRPCProvider() method that tf5muxserver can mux against. That SDK
+// migration, and the framework-based provider tree it would be muxed
+// with, are not present in this snapshot. This main is left as a plain
+// plugin.Serve of the legacy provider rather than a mux server that
+// couldn't compile; the mux itself is follow-up work, tracked by this
+// comment, not something this commit closes out.
+func main() {
+	plugin.Serve(&plugin.ServeOpts{
+		ProviderFunc: func() terraform.ResourceProvider {
+			return Provider(providerConfig)
+		},
+	})
+}
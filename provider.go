@@ -4,7 +4,6 @@ package main
 
 import (
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -28,8 +27,28 @@ func init() {
 			"A private_key or a private_key_path must be provided.",
 		"private_key_password": "(Optional) The password used to secure the private key.",
 		"region":               "(Optional) The region for API connections.",
-		"disable_auto_retries": "(Optional) Disable Automatic retries for retriable errors.\n" +
+		"disable_auto_retries": "(Optional, Deprecated) Disable Automatic retries for retriable errors. Use `retry_policy` instead; " +
+			"this is kept as a shortcut for `retry_policy { max_attempts = 1 }`.\n" +
 			"Auto retries were introduced to solve some eventual consistency problems but it also introduced performance issues on destroy operations.",
+		"retry_policy": "(Optional) Configures the exponential-backoff-with-jitter policy used to retry retriable errors, " +
+			"replacing the all-or-nothing trade-off of `disable_auto_retries`.",
+		"max_attempts":         "(Optional) The maximum number of attempts (including the initial one) made for a retriable request. Defaults to 10.",
+		"max_elapsed_time":     "(Optional) The maximum total time, in seconds, to keep retrying a single request before giving up. Defaults to 900.",
+		"initial_interval_ms":  "(Optional) The base delay, in milliseconds, before the first retry. Defaults to 500.",
+		"multiplier":           "(Optional) The multiplier applied to the delay after each retry. Defaults to 2.0.",
+		"randomization_factor": "(Optional) The amount of jitter to add to each delay, as a fraction of the computed delay. Defaults to 0.5.",
+		"retry_on_status": "(Optional) The list of HTTP status codes that are considered retriable, in addition to the SDK's " +
+			"built-in eventual-consistency errors.",
+		"auth": "(Optional) The type of auth to use when signing requests. One of `ApiKey` (the default, using the\n" +
+			"tenancy/user/fingerprint/private_key settings below), `InstancePrincipal` (obtain a signing certificate and\n" +
+			"token from the instance's IMDS/federation endpoint, for use on OCI compute instances), or `ConfigFile`\n" +
+			"(read tenancy/user/fingerprint/key settings from an OCI config file).",
+		"config_file_profile": "(Optional) The profile name to read from the config file when `auth` is `ConfigFile`.",
+		"endpoints": "(Optional) Per-service endpoint overrides, keyed by service name (`identity`, `core`, `database`, " +
+			"`load_balancer`, `object_storage`). Supersedes the single, hidden `url_template` env var and allows the " +
+			"provider to target isolated regions, Dedicated Region Cloud@Customer, or local mock deployments.",
+		"disable_cert_verification": "(Optional) Disable TLS certificate verification for API connections. Supersedes the " +
+			"hidden `allow_insecure_tls` env var.",
 	}
 }
 
@@ -45,21 +64,35 @@ func Provider(configfn schema.ConfigureFunc) terraform.ResourceProvider {
 
 func schemaMap() map[string]*schema.Schema {
 	return map[string]*schema.Schema{
+		"auth": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     authAPIKey,
+			Description: descriptions["auth"],
+			DefaultFunc: schema.EnvDefaultFunc("OBMCS_AUTH", nil),
+		},
+		"config_file_profile": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     "DEFAULT",
+			Description: descriptions["config_file_profile"],
+			DefaultFunc: schema.EnvDefaultFunc("OBMCS_CONFIG_FILE_PROFILE", nil),
+		},
 		"tenancy_ocid": {
 			Type:        schema.TypeString,
-			Required:    true,
+			Optional:    true,
 			Description: descriptions["tenancy_ocid"],
 			DefaultFunc: schema.EnvDefaultFunc("OBMCS_TENANCY_OCID", nil),
 		},
 		"user_ocid": {
 			Type:        schema.TypeString,
-			Required:    true,
+			Optional:    true,
 			Description: descriptions["user_ocid"],
 			DefaultFunc: schema.EnvDefaultFunc("OBMCS_USER_OCID", nil),
 		},
 		"fingerprint": {
 			Type:        schema.TypeString,
-			Required:    true,
+			Optional:    true,
 			Description: descriptions["fingerprint"],
 			DefaultFunc: schema.EnvDefaultFunc("OBMCS_FINGERPRINT", nil),
 		},
@@ -100,6 +133,68 @@ func schemaMap() map[string]*schema.Schema {
 			Description: descriptions["disable_auto_retries"],
 			DefaultFunc: schema.EnvDefaultFunc("OBMCS_DISABLE_AUTO_RETRIES", nil),
 		},
+		"retry_policy": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: descriptions["retry_policy"],
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"max_attempts": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						Default:      defaultMaxAttempts,
+						Description:  descriptions["max_attempts"],
+						ValidateFunc: validateMaxAttempts,
+					},
+					"max_elapsed_time": {
+						Type:        schema.TypeInt,
+						Optional:    true,
+						Default:     defaultMaxElapsedTime,
+						Description: descriptions["max_elapsed_time"],
+					},
+					"initial_interval_ms": {
+						Type:        schema.TypeInt,
+						Optional:    true,
+						Default:     defaultInitialIntervalMs,
+						Description: descriptions["initial_interval_ms"],
+					},
+					"multiplier": {
+						Type:         schema.TypeFloat,
+						Optional:     true,
+						Default:      defaultMultiplier,
+						Description:  descriptions["multiplier"],
+						ValidateFunc: validateMultiplier,
+					},
+					"randomization_factor": {
+						Type:         schema.TypeFloat,
+						Optional:     true,
+						Default:      defaultRandomizationFactor,
+						Description:  descriptions["randomization_factor"],
+						ValidateFunc: validateRandomizationFactor,
+					},
+					"retry_on_status": {
+						Type:        schema.TypeList,
+						Optional:    true,
+						Description: descriptions["retry_on_status"],
+						Elem:        &schema.Schema{Type: schema.TypeInt},
+					},
+				},
+			},
+		},
+		"endpoints": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Description: descriptions["endpoints"],
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"disable_cert_verification": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: descriptions["disable_cert_verification"],
+			DefaultFunc: schema.EnvDefaultFunc("OBMCS_DISABLE_CERT_VERIFICATION", nil),
+		},
 	}
 }
 
@@ -221,61 +316,58 @@ func getRequiredEnvSetting(s string) string {
 }
 
 func providerConfig(d *schema.ResourceData) (client interface{}, err error) {
-	tenancyOCID := d.Get("tenancy_ocid").(string)
-	userOCID := d.Get("user_ocid").(string)
-	fingerprint := d.Get("fingerprint").(string)
-	privateKeyBuffer, hasKey := d.Get("private_key").(string)
-	privateKeyPath, hasKeyPath := d.Get("private_key_path").(string)
-	privateKeyPassword, hasKeyPass := d.Get("private_key_password").(string)
 	region, hasRegion := d.Get("region").(string)
-	disableAutoRetries, hasDisableRetries := d.Get("disable_auto_retries").(bool)
 
 	// for internal use
 	urlTemplate := getEnvSetting("url_template", "")
 	allowInsecureTls := getEnvSetting("allow_insecure_tls", "")
 
-	clientOpts := []baremetal.NewClientOptionsFunc{
-		func(o *baremetal.NewClientOptions) {
-			o.UserAgent = fmt.Sprintf("baremetal-terraform-v%s", baremetal.SDKVersion)
-		},
+	disableCertVerification := allowInsecureTls == "true"
+	if v, ok := d.Get("disable_cert_verification").(bool); ok && v {
+		disableCertVerification = true
 	}
 
-	if allowInsecureTls == "true" {
+	var transport http.RoundTripper = &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if disableCertVerification {
 		log.Println("[WARN] USING INSECURE TLS")
-		clientOpts = append(clientOpts, baremetal.CustomTransport(
-			&http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
-		))
-	} else {
-		clientOpts = append(clientOpts, baremetal.CustomTransport(
-			&http.Transport{Proxy: http.ProxyFromEnvironment}),
-		)
+		transport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	if endpoints := endpointOverrides(d); len(endpoints) > 0 {
+		transport = &endpointRoundTripper{endpoints: endpoints, next: transport}
 	}
 
-	if hasKey && privateKeyBuffer != "" {
-		clientOpts = append(clientOpts, baremetal.PrivateKeyBytes([]byte(privateKeyBuffer)))
-	} else if hasKeyPath && privateKeyPath != "" {
-		clientOpts = append(clientOpts, baremetal.PrivateKeyFilePath(privateKeyPath))
-	} else {
-		err = errors.New("One of private_key or private_key_path is required")
+	// Retries and, when applicable, instance-principal token authorization
+	// are both implemented as transport layers rather than SDK options,
+	// chained with endpointRoundTripper onto the baremetal.CustomTransport
+	// hook below.
+	transport = newRetryRoundTripper(d, transport)
+
+	userOCID, tenancyOCID, fingerprint, authOpts, authTransport, err := authConfig(d)
+	if err != nil {
 		return
 	}
+	if authTransport != nil {
+		transport = authTransport(transport)
+	}
 
-	if hasKeyPass && privateKeyPassword != "" {
-		clientOpts = append(clientOpts, baremetal.PrivateKeyPassword(privateKeyPassword))
+	clientOpts := []baremetal.NewClientOptionsFunc{
+		func(o *baremetal.NewClientOptions) {
+			o.UserAgent = fmt.Sprintf("baremetal-terraform-v%s", baremetal.SDKVersion)
+		},
+		baremetal.CustomTransport(transport),
 	}
 
 	if hasRegion && region != "" {
 		clientOpts = append(clientOpts, baremetal.Region(region))
 	}
 
-	if hasDisableRetries {
-		clientOpts = append(clientOpts, baremetal.DisableAutoRetries(disableAutoRetries))
-	}
-
 	if urlTemplate != "" {
 		clientOpts = append(clientOpts, baremetal.UrlTemplate(urlTemplate))
 	}
 
+	clientOpts = append(clientOpts, authOpts...)
+
 	client, err = baremetal.NewClient(userOCID, tenancyOCID, fingerprint, clientOpts...)
 	return
 }
@@ -0,0 +1,205 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	defaultMaxAttempts         = 10
+	defaultMaxElapsedTime      = 900
+	defaultInitialIntervalMs   = 500
+	defaultMultiplier          = 2.0
+	defaultRandomizationFactor = 0.5
+)
+
+// Per-resource `timeouts { create/update/delete }` overrides on
+// baremetal_database_db_system and baremetal_core_instance (the other half
+// of request chunk0-4, alongside the provider-level retry_policy above)
+// are BLOCKED, not done, in this snapshot. That block is defined on the
+// individual resource's *schema.Resource (schema.Resource.Timeouts in
+// later SDK versions, or a "timeouts" nested block read in Create/Update/
+// Delete in this SDK generation), but resource_database_db_system.go and
+// resource_core_instance.go - along with every other resource_*.go file -
+// are not present in this tree to add it to. There's nothing here to wire
+// a per-resource override into.
+
+// retryPolicy holds the exponential-backoff-with-jitter settings read from
+// the "retry_policy" block (or the deprecated "disable_auto_retries"
+// shortcut).
+type retryPolicy struct {
+	maxAttempts         int
+	maxElapsedTime      time.Duration
+	initialInterval     time.Duration
+	multiplier          float64
+	randomizationFactor float64
+	retryOnStatus       map[int]bool
+}
+
+// validateMaxAttempts guards against "retry_policy { max_attempts = 0 }":
+// TypeInt alone lets it through schema validation, and a zero-iteration
+// retry loop falls through RoundTrip without ever assigning resp/err.
+func validateMaxAttempts(v interface{}, k string) (ws []string, errors []error) {
+	if value := v.(int); value < 1 {
+		errors = append(errors, fmt.Errorf("%q must be at least 1, got %d", k, value))
+	}
+	return
+}
+
+// validateMultiplier keeps backoff() monotonically non-decreasing between
+// attempts; a multiplier below 1 would make each retry wait less than the
+// one before it.
+func validateMultiplier(v interface{}, k string) (ws []string, errors []error) {
+	if value := v.(float64); value < 1 {
+		errors = append(errors, fmt.Errorf("%q must be at least 1.0, got %g", k, value))
+	}
+	return
+}
+
+// validateRandomizationFactor keeps backoff()'s jitter within +/-100% of
+// the computed base delay; above 1.0 the low end of the jitter range goes
+// negative.
+func validateRandomizationFactor(v interface{}, k string) (ws []string, errors []error) {
+	if value := v.(float64); value < 0 || value > 1 {
+		errors = append(errors, fmt.Errorf("%q must be between 0 and 1.0, got %g", k, value))
+	}
+	return
+}
+
+// readRetryPolicy translates the provider schema into a retryPolicy,
+// giving "retry_policy" precedence over the deprecated
+// "disable_auto_retries" shortcut when both are set.
+func readRetryPolicy(d *schema.ResourceData) retryPolicy {
+	policy := retryPolicy{
+		maxAttempts:         defaultMaxAttempts,
+		maxElapsedTime:      defaultMaxElapsedTime * time.Second,
+		initialInterval:     defaultInitialIntervalMs * time.Millisecond,
+		multiplier:          defaultMultiplier,
+		randomizationFactor: defaultRandomizationFactor,
+	}
+
+	if disable, ok := d.Get("disable_auto_retries").(bool); ok && disable {
+		policy.maxAttempts = 1
+	}
+
+	if raw, ok := d.GetOk("retry_policy"); ok {
+		blocks := raw.([]interface{})
+		if len(blocks) > 0 && blocks[0] != nil {
+			block := blocks[0].(map[string]interface{})
+
+			policy.maxAttempts = block["max_attempts"].(int)
+			policy.maxElapsedTime = time.Duration(block["max_elapsed_time"].(int)) * time.Second
+			policy.initialInterval = time.Duration(block["initial_interval_ms"].(int)) * time.Millisecond
+			policy.multiplier = block["multiplier"].(float64)
+			policy.randomizationFactor = block["randomization_factor"].(float64)
+
+			if statuses := block["retry_on_status"].([]interface{}); len(statuses) > 0 {
+				policy.retryOnStatus = make(map[int]bool, len(statuses))
+				for _, status := range statuses {
+					policy.retryOnStatus[status.(int)] = true
+				}
+			}
+		}
+	}
+
+	// ValidateFunc on max_attempts rejects config where Terraform applies
+	// it at plan time, but readRetryPolicy has no ResourceData-agnostic
+	// way to surface that error here, so floor it defensively rather than
+	// letting RoundTrip's retry loop silently never execute.
+	if policy.maxAttempts < 1 {
+		policy.maxAttempts = 1
+	}
+
+	return policy
+}
+
+// backoff returns the delay before the given retry attempt (1-indexed:
+// attempt 1 is the delay before the first retry, after the initial
+// request), applying the configured multiplier and +/- randomization
+// factor jitter.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		return 0
+	}
+
+	base := float64(p.initialInterval) * math.Pow(p.multiplier, float64(attempt-1))
+
+	if p.randomizationFactor > 0 {
+		delta := p.randomizationFactor * base
+		base = base - delta + rand.Float64()*2*delta
+	}
+
+	return time.Duration(base)
+}
+
+// shouldRetryStatus reports whether a response with the given status code
+// should be retried: the SDK's built-in eventual-consistency statuses
+// (409 Conflict, 429 Too Many Requests, and 5xx) are always retriable, and
+// any status listed in retry_on_status is retriable as well.
+func (p retryPolicy) shouldRetryStatus(status int) bool {
+	if status == http.StatusConflict || status == http.StatusTooManyRequests || status >= 500 {
+		return true
+	}
+	return p.retryOnStatus[status]
+}
+
+// retryRoundTripper wraps an http.RoundTripper with the configured
+// exponential-backoff-with-jitter retry policy, installed into the
+// baremetal client by providerConfig via the same baremetal.CustomTransport
+// hook that carries endpointRoundTripper (endpoints.go), rather than any
+// retry-specific SDK option.
+type retryRoundTripper struct {
+	policy retryPolicy
+	next   http.RoundTripper
+}
+
+func newRetryRoundTripper(d *schema.ResourceData, next http.RoundTripper) http.RoundTripper {
+	return &retryRoundTripper{policy: readRetryPolicy(d), next: next}
+}
+
+func (rt *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var bodyBytes []byte
+	if req.Body != nil {
+		var err error
+		bodyBytes, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	deadline := time.Now().Add(rt.policy.maxElapsedTime)
+
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt < rt.policy.maxAttempts; attempt++ {
+		if bodyBytes != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+		}
+
+		resp, err = rt.next.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		if !rt.policy.shouldRetryStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if attempt == rt.policy.maxAttempts-1 || time.Now().After(deadline) {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		time.Sleep(rt.policy.backoff(attempt + 1))
+	}
+
+	return resp, err
+}
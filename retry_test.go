@@ -0,0 +1,244 @@
+// Copyright (c) 2017, Oracle and/or its affiliates. All rights reserved.
+
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestReadRetryPolicyPrefersRetryPolicyBlockOverDisableAutoRetries(t *testing.T) {
+	raw := map[string]interface{}{
+		"disable_auto_retries": true,
+		"retry_policy": []interface{}{
+			map[string]interface{}{
+				"max_attempts":         int(5),
+				"max_elapsed_time":     int(60),
+				"initial_interval_ms":  int(250),
+				"multiplier":           float64(3),
+				"randomization_factor": float64(0.1),
+				"retry_on_status":      []interface{}{},
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, schemaMap(), raw)
+	policy := readRetryPolicy(d)
+
+	if policy.maxAttempts != 5 {
+		t.Errorf("maxAttempts = %d, want 5 (retry_policy should win over disable_auto_retries)", policy.maxAttempts)
+	}
+}
+
+func TestReadRetryPolicyFallsBackToDisableAutoRetries(t *testing.T) {
+	raw := map[string]interface{}{
+		"disable_auto_retries": true,
+	}
+
+	d := schema.TestResourceDataRaw(t, schemaMap(), raw)
+	policy := readRetryPolicy(d)
+
+	if policy.maxAttempts != 1 {
+		t.Errorf("maxAttempts = %d, want 1 when disable_auto_retries is set and no retry_policy block is present", policy.maxAttempts)
+	}
+}
+
+func TestReadRetryPolicyFloorsMaxAttemptsAtOne(t *testing.T) {
+	raw := map[string]interface{}{
+		"retry_policy": []interface{}{
+			map[string]interface{}{
+				"max_attempts":         int(0),
+				"max_elapsed_time":     int(60),
+				"initial_interval_ms":  int(250),
+				"multiplier":           float64(2),
+				"randomization_factor": float64(0.1),
+				"retry_on_status":      []interface{}{},
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, schemaMap(), raw)
+	policy := readRetryPolicy(d)
+
+	if policy.maxAttempts != 1 {
+		t.Errorf("maxAttempts = %d, want 1 (floored so RoundTrip's loop always executes at least once)", policy.maxAttempts)
+	}
+}
+
+func TestValidateMaxAttempts(t *testing.T) {
+	cases := []struct {
+		value   int
+		wantErr bool
+	}{
+		{0, true},
+		{-1, true},
+		{1, false},
+		{10, false},
+	}
+
+	for _, c := range cases {
+		_, errs := validateMaxAttempts(c.value, "max_attempts")
+		if (len(errs) > 0) != c.wantErr {
+			t.Errorf("validateMaxAttempts(%d) errors = %v, wantErr %v", c.value, errs, c.wantErr)
+		}
+	}
+}
+
+func TestValidateMultiplier(t *testing.T) {
+	cases := []struct {
+		value   float64
+		wantErr bool
+	}{
+		{0.5, true},
+		{1, false},
+		{2, false},
+	}
+
+	for _, c := range cases {
+		_, errs := validateMultiplier(c.value, "multiplier")
+		if (len(errs) > 0) != c.wantErr {
+			t.Errorf("validateMultiplier(%g) errors = %v, wantErr %v", c.value, errs, c.wantErr)
+		}
+	}
+}
+
+func TestValidateRandomizationFactor(t *testing.T) {
+	cases := []struct {
+		value   float64
+		wantErr bool
+	}{
+		{-0.1, true},
+		{0, false},
+		{0.5, false},
+		{1, false},
+		{1.1, true},
+	}
+
+	for _, c := range cases {
+		_, errs := validateRandomizationFactor(c.value, "randomization_factor")
+		if (len(errs) > 0) != c.wantErr {
+			t.Errorf("validateRandomizationFactor(%g) errors = %v, wantErr %v", c.value, errs, c.wantErr)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffGrowsWithMultiplier(t *testing.T) {
+	p := retryPolicy{
+		initialInterval:     100 * time.Millisecond,
+		multiplier:          2.0,
+		randomizationFactor: 0,
+	}
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: 0},
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 3, want: 400 * time.Millisecond},
+	}
+
+	for _, c := range cases {
+		if got := p.backoff(c.attempt); got != c.want {
+			t.Errorf("backoff(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffJitterStaysInBounds(t *testing.T) {
+	p := retryPolicy{
+		initialInterval:     100 * time.Millisecond,
+		multiplier:          1,
+		randomizationFactor: 0.5,
+	}
+
+	for i := 0; i < 100; i++ {
+		got := p.backoff(1)
+		if got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("backoff(1) = %v, want within [50ms, 150ms]", got)
+		}
+	}
+}
+
+func TestRetryPolicyShouldRetryStatus(t *testing.T) {
+	p := retryPolicy{retryOnStatus: map[int]bool{http.StatusBadGateway: true}}
+
+	cases := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusConflict, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusNotFound, false},
+	}
+
+	for _, c := range cases {
+		if got := p.shouldRetryStatus(c.status); got != c.want {
+			t.Errorf("shouldRetryStatus(%d) = %v, want %v", c.status, got, c.want)
+		}
+	}
+}
+
+// fakeRoundTripper lets tests control exactly what the retry round
+// tripper sees from the wrapped transport.
+type fakeRoundTripper struct {
+	responses []int
+	calls     int
+}
+
+func (f *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := f.responses[f.calls]
+	f.calls++
+	return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+}
+
+func TestRetryRoundTripperStopsOnSuccess(t *testing.T) {
+	fake := &fakeRoundTripper{responses: []int{http.StatusInternalServerError, http.StatusOK}}
+	rt := &retryRoundTripper{
+		policy: retryPolicy{maxAttempts: 5, initialInterval: 0, multiplier: 1, maxElapsedTime: time.Second},
+		next:   fake,
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("final status = %d, want 200", resp.StatusCode)
+	}
+	if fake.calls != 2 {
+		t.Errorf("calls = %d, want 2", fake.calls)
+	}
+}
+
+func TestRetryRoundTripperRespectsMaxAttempts(t *testing.T) {
+	fake := &fakeRoundTripper{responses: []int{
+		http.StatusInternalServerError,
+		http.StatusInternalServerError,
+		http.StatusInternalServerError,
+	}}
+	rt := &retryRoundTripper{
+		policy: retryPolicy{maxAttempts: 3, initialInterval: 0, multiplier: 1, maxElapsedTime: time.Second},
+		next:   fake,
+	}
+
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("final status = %d, want 500", resp.StatusCode)
+	}
+	if fake.calls != 3 {
+		t.Errorf("calls = %d, want 3 (maxAttempts)", fake.calls)
+	}
+}